@@ -0,0 +1,121 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+
+	"github.com/timescale/promscale/pkg/migration-tool/utils"
+)
+
+// fileConfig mirrors config, but with exported fields and struct tags so it
+// can be unmarshalled from a YAML or TOML config file. Zero-valued fields
+// are treated as "not set in the file" by applyFileConfig, so that CLI flags
+// registered afterwards with conf's current value as their default continue
+// to take precedence.
+type fileConfig struct {
+	Name string `yaml:"name" toml:"name"`
+
+	// Mint/Maxt are pointers, like ProgressEnabled below, so that a file
+	// which explicitly sets mint/maxt to 0 (a valid Unix-epoch timestamp) is
+	// distinguishable from a file that doesn't mention them at all.
+	Mint *int64 `yaml:"mint" toml:"mint"`
+	Maxt *int64 `yaml:"maxt" toml:"maxt"`
+
+	ReadURL  string `yaml:"read_url" toml:"read_url"`
+	WriteURL string `yaml:"write_url" toml:"write_url"`
+
+	ProgressMetricName string `yaml:"progress_metric_name" toml:"progress_metric_name"`
+	ProgressMetricURL  string `yaml:"progress_metric_url" toml:"progress_metric_url"`
+	ProgressFile       string `yaml:"progress_file" toml:"progress_file"`
+	ProgressEnabled    *bool  `yaml:"progress_enabled" toml:"progress_enabled"`
+
+	MaxBlockSize string `yaml:"max_read_size" toml:"max_read_size"`
+	NumShards    int    `yaml:"num_shards" toml:"num_shards"`
+
+	RelabelConfigFile string `yaml:"relabel_config" toml:"relabel_config"`
+
+	// ReaderAuth/WriterAuth let a config file express auth as a nested
+	// section instead of the flat -read-auth-*/-write-auth-* flags.
+	ReaderAuth utils.Auth `yaml:"reader_auth" toml:"reader_auth"`
+	WriterAuth utils.Auth `yaml:"writer_auth" toml:"writer_auth"`
+}
+
+// loadConfigFile reads path (YAML by default, TOML if the extension is
+// .toml) and merges any values it sets into conf. It must be called before
+// the CLI flags are registered, so that flags given on the command line can
+// still override whatever the file set.
+func loadConfigFile(conf *config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file %q: %w", path, err)
+	}
+
+	var fc fileConfig
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		if err := toml.Unmarshal(data, &fc); err != nil {
+			return fmt.Errorf("parsing TOML config file %q: %w", path, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &fc); err != nil {
+			return fmt.Errorf("parsing YAML config file %q: %w", path, err)
+		}
+	}
+
+	applyFileConfig(conf, &fc)
+	return nil
+}
+
+func applyFileConfig(conf *config, fc *fileConfig) {
+	if fc.Name != "" {
+		conf.name = fc.Name
+	}
+	if fc.Mint != nil {
+		conf.mintSec = *fc.Mint
+		conf.mintSet = true
+	}
+	if fc.Maxt != nil {
+		conf.maxtSec = *fc.Maxt
+	}
+	if fc.ReadURL != "" {
+		conf.readURL = fc.ReadURL
+	}
+	if fc.WriteURL != "" {
+		conf.writeURL = fc.WriteURL
+	}
+	if fc.ProgressMetricName != "" {
+		conf.progressMetricName = fc.ProgressMetricName
+	}
+	if fc.ProgressMetricURL != "" {
+		conf.progressMetricURL = fc.ProgressMetricURL
+	}
+	if fc.ProgressFile != "" {
+		conf.progressFile = fc.ProgressFile
+	}
+	if fc.ProgressEnabled != nil {
+		conf.progressEnabled = *fc.ProgressEnabled
+	}
+	if fc.MaxBlockSize != "" {
+		conf.maxBlockSize = fc.MaxBlockSize
+	}
+	if fc.NumShards != 0 {
+		conf.numShards = fc.NumShards
+	}
+	if fc.RelabelConfigFile != "" {
+		conf.relabelConfigFile = fc.RelabelConfigFile
+	}
+	if (fc.ReaderAuth != utils.Auth{}) {
+		conf.readerAuth = fc.ReaderAuth
+	}
+	if (fc.WriterAuth != utils.Auth{}) {
+		conf.writerAuth = fc.WriterAuth
+	}
+}
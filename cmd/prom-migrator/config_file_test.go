@@ -0,0 +1,132 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/timescale/promscale/pkg/migration-tool/utils"
+)
+
+func TestLoadConfigFileYAML(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	contents := `
+mint: 1000
+read_url: http://localhost:9090/api/v1/read
+write_url: http://localhost:9201/write
+progress_metric_name: custom_progress
+reader_auth:
+  password: reader-pass
+writer_auth:
+  bearer_token: writer-token
+`
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	conf := new(config)
+	parseFlags(conf, []string{"-config=" + path})
+
+	assert.Equal(t, int64(1000), conf.mintSec)
+	assert.Equal(t, "http://localhost:9090/api/v1/read", conf.readURL)
+	assert.Equal(t, "http://localhost:9201/write", conf.writeURL)
+	assert.Equal(t, "custom_progress", conf.progressMetricName)
+	assert.Equal(t, utils.Auth{Password: "reader-pass"}, conf.readerAuth)
+	assert.Equal(t, utils.Auth{BearerToken: "writer-token"}, conf.writerAuth)
+}
+
+func TestLoadConfigFileTOML(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	contents := `
+mint = 1000
+read_url = "http://localhost:9090/api/v1/read"
+write_url = "http://localhost:9201/write"
+
+[reader_auth]
+bearer_token = "reader-token"
+
+[reader_auth.tls_config]
+ca_file = "/tmp/ca.pem"
+cert_file = "/tmp/cert.pem"
+key_file = "/tmp/key.pem"
+server_name = "prometheus.internal"
+insecure_skip_verify = true
+`
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	conf := new(config)
+	parseFlags(conf, []string{"-config=" + path})
+
+	assert.Equal(t, int64(1000), conf.mintSec)
+	assert.Equal(t, "http://localhost:9090/api/v1/read", conf.readURL)
+	assert.Equal(t, "http://localhost:9201/write", conf.writeURL)
+	assert.Equal(t, utils.Auth{
+		BearerToken: "reader-token",
+		TLSConfig: utils.TLSConfig{
+			CAFile:             "/tmp/ca.pem",
+			CertFile:           "/tmp/cert.pem",
+			KeyFile:            "/tmp/key.pem",
+			ServerName:         "prometheus.internal",
+			InsecureSkipVerify: true,
+		},
+	}, conf.readerAuth, "every TLSConfig/Auth field must have a toml tag, or BurntSushi's case-fold fallback silently zeroes it out")
+}
+
+func TestLoadConfigFileFlagOverride(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	contents := `
+mint: 1000
+read_url: http://localhost:9090/api/v1/read
+write_url: http://localhost:9201/write
+`
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	conf := new(config)
+	parseFlags(conf, []string{"-config=" + path, "-read-url=http://localhost:9999/api/v1/read"})
+
+	assert.Equal(t, int64(1000), conf.mintSec)
+	assert.Equal(t, "http://localhost:9999/api/v1/read", conf.readURL, "CLI flags must override config file values")
+	assert.Equal(t, "http://localhost:9201/write", conf.writeURL)
+}
+
+func TestLoadConfigFileMintZero(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	contents := `
+mint: 0
+read_url: http://localhost:9090/api/v1/read
+write_url: http://localhost:9201/write
+progress_enabled: false
+`
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	conf := new(config)
+	parseFlags(conf, []string{"-config=" + path})
+
+	assert.Equal(t, int64(0), conf.mintSec)
+	assert.True(t, conf.mintSet, "a literal mint: 0 in the config file must count as set")
+	assert.NoError(t, validateConf(conf), "mint: 0 is a valid Unix-epoch mint and must not be rejected as \"not provided\"")
+}
+
+func TestLoadConfigFileNotFound(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	conf := new(config)
+	parseFlags(conf, []string{"-config=/does/not/exist.yaml", "-mint=1000", "-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write"})
+
+	err := validateConf(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading config file")
+}
@@ -0,0 +1,417 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/pkg/relabel"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/timescale/promscale/pkg/migration-tool/planner"
+	"github.com/timescale/promscale/pkg/migration-tool/reader"
+	"github.com/timescale/promscale/pkg/migration-tool/utils"
+	"github.com/timescale/promscale/pkg/migration-tool/writer"
+)
+
+// metricNameRegex matches the same character set Prometheus uses for metric
+// names: https://prometheus.io/docs/concepts/data_model/#metric-names-and-labels.
+var metricNameRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// config holds every setting that drives a single prom-migrator run. Values
+// may come from a config file (see config_file.go), CLI flags, or the
+// built-in defaults set in parseFlags, in that order of precedence.
+type config struct {
+	name string
+
+	// mint/maxt are the millisecond timestamps derived from mintSec/maxtSec
+	// once flags have been parsed; see convertSecFlagToMs.
+	mint    int64
+	mintSec int64
+	maxt    int64
+	maxtSec int64
+
+	// mintSet records whether mint was actually provided, by -mint or by a
+	// config file, as opposed to being left at its zero value. mintSec == 0
+	// is otherwise ambiguous between "not set" and "explicitly set to the
+	// Unix epoch", and validateConf needs to tell those apart.
+	mintSet bool
+
+	readURL  string
+	writeURL string
+
+	progressMetricName string
+	progressMetricURL  string
+	progressFile       string
+	progressEnabled    bool
+
+	maxBlockSize string
+	numShards    int
+
+	readerAuth utils.Auth
+	writerAuth utils.Auth
+
+	relabelConfigFile string
+	relabelConfigs    []*relabel.Config
+
+	// configFileErr records a failure to load/parse the -config file, if
+	// any, so it can be surfaced alongside the rest of the validation
+	// errors in validateConf instead of aborting flag parsing outright.
+	configFileErr error
+}
+
+func main() {
+	conf := new(config)
+	parseFlags(conf, os.Args[1:])
+	if err := validateConf(conf); err != nil {
+		log.Fatalf("could not validate config: %s", err.Error())
+	}
+
+	readerTransport, err := conf.readerAuth.NewTransport()
+	if err != nil {
+		log.Fatalf("could not build reader transport: %s", err.Error())
+	}
+	writerTransport, err := conf.writerAuth.NewTransport()
+	if err != nil {
+		log.Fatalf("could not build writer transport: %s", err.Error())
+	}
+
+	if conf.progressEnabled {
+		mint, err := seedMint(conf, readerTransport)
+		if err != nil {
+			log.Fatalf("could not seed mint from progress: %s", err.Error())
+		}
+		conf.mint = mint
+	}
+
+	log.Printf("migrating from %q to %q in the time-range [%d, %d] across %d shard(s)", conf.readURL, conf.writeURL, conf.mint, conf.maxt, conf.numShards)
+
+	rdr := reader.New(&http.Client{Transport: readerTransport}, conf.readURL)
+	wtr := writer.New(&http.Client{Transport: writerTransport}, conf.writeURL)
+
+	maxBlockBytes, err := parseByteSize(conf.maxBlockSize)
+	if err != nil {
+		log.Fatalf("could not parse max-read-size: %s", err.Error())
+	}
+
+	if err := migrate(rdr, wtr, conf, maxBlockBytes); err != nil {
+		log.Fatalf("could not migrate: %s", err.Error())
+	}
+}
+
+// migrate splits [conf.mint, conf.maxt] into conf.numShards blocks and
+// migrates them concurrently, at most conf.numShards in flight at once. If
+// conf.progressFile is set, it's checkpointed to the maxt of the oldest run
+// of contiguous, already-completed blocks, so a resumed run never skips a
+// block that hasn't actually finished, even though blocks can complete out
+// of order.
+func migrate(rdr *reader.Reader, wtr *writer.Writer, conf *config, maxBlockBytes int64) error {
+	blocks := planner.SplitBlocks(conf.mint, conf.maxt, conf.numShards)
+
+	concurrency := conf.numShards
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu           sync.Mutex
+		done         = make([]bool, len(blocks))
+		checkpointed = -1
+		firstErr     error
+	)
+	advanceCheckpoint := func() {
+		if !conf.progressEnabled || conf.progressFile == "" {
+			return
+		}
+		mu.Lock()
+		i := checkpointed + 1
+		for i < len(done) && done[i] {
+			i++
+		}
+		if i == checkpointed+1 {
+			mu.Unlock()
+			return
+		}
+		maxt := blocks[i-1].Maxt
+		checkpointed = i - 1
+		mu.Unlock()
+
+		if err := planner.WriteCheckpoint(conf.progressFile, maxt); err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("checkpointing progress: %w", err)
+			}
+			mu.Unlock()
+		}
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, b := range blocks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, b planner.Block) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := migrateBlock(rdr, wtr, conf.relabelConfigs, b, maxBlockBytes); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+
+			mu.Lock()
+			done[i] = true
+			mu.Unlock()
+			advanceCheckpoint()
+		}(i, b)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// migrateBlock reads b once, relabels its series, and writes them
+// downstream, splitting the already-fetched series (rather than re-reading
+// a narrower time-range from the source) into smaller write requests if the
+// full set would exceed maxBlockBytes.
+func migrateBlock(rdr *reader.Reader, wtr *writer.Writer, cfgs []*relabel.Config, b planner.Block, maxBlockBytes int64) error {
+	series, err := rdr.Read(b.Mint, b.Maxt)
+	if err != nil {
+		return fmt.Errorf("reading block [%d, %d]: %w", b.Mint, b.Maxt, err)
+	}
+	series = planner.ApplyRelabeling(series, cfgs)
+	return writeInChunks(wtr, series, maxBlockBytes)
+}
+
+// writeInChunks writes series to wtr, halving it by series count (not by
+// re-reading a narrower time-range) whenever the estimated remote-write
+// payload would exceed maxBlockBytes, so a single oversized block doesn't
+// turn into extra round trips to the read-storage endpoint.
+func writeInChunks(wtr *writer.Writer, series []prompb.TimeSeries, maxBlockBytes int64) error {
+	if maxBlockBytes > 0 && len(series) > 1 && planner.EstimateSize(series) > maxBlockBytes {
+		mid := len(series) / 2
+		if err := writeInChunks(wtr, series[:mid], maxBlockBytes); err != nil {
+			return err
+		}
+		return writeInChunks(wtr, series[mid:], maxBlockBytes)
+	}
+
+	if err := wtr.Write(series); err != nil {
+		return fmt.Errorf("writing %d series: %w", len(series), err)
+	}
+	return nil
+}
+
+// seedMint resumes conf.mint from progress: from progressFile if one is
+// configured, otherwise by querying progressMetricURL (or, if that's unset,
+// readURL) for the last value of progressMetricName.
+func seedMint(conf *config, readerTransport http.RoundTripper) (int64, error) {
+	if conf.progressFile != "" {
+		return planner.SeedMint(conf.progressFile, conf.mint)
+	}
+	metricURL := conf.progressMetricURL
+	if metricURL == "" {
+		metricURL = conf.readURL
+	}
+	client := &http.Client{Transport: readerTransport}
+	return planner.SeedMintFromMetric(client, metricURL, conf.progressMetricName, conf.mint)
+}
+
+// parseFlags applies the built-in defaults, merges in a -config file if one
+// is given, and finally parses the CLI flags in args. CLI flags always take
+// precedence over the config file, which in turn takes precedence over the
+// built-in defaults.
+func parseFlags(conf *config, args []string) {
+	conf.name = "prom-migrator"
+	conf.maxtSec = time.Now().Unix()
+	conf.progressMetricName = "prom_migrator_progress"
+	conf.progressEnabled = true
+	conf.maxBlockSize = "500MB"
+	conf.numShards = 4
+
+	if configFile := extractConfigFlag(args); configFile != "" {
+		if err := loadConfigFile(conf, configFile); err != nil {
+			conf.configFileErr = err
+		}
+	}
+
+	var discardedConfigFlag string
+	flag.StringVar(&discardedConfigFlag, "config", "", "Path to a YAML or TOML config file. CLI flags take precedence over values loaded from this file.")
+	flag.StringVar(&conf.name, "name", conf.name, "Name for prom-migrator in order to check its identity as a remote read/write client. "+
+		"Ideally, it should be set to a unique value to avoid any conflicts.")
+	flag.Int64Var(&conf.mintSec, "mint", conf.mintSec, "Minimum timestamp (Unix seconds) for the migration.")
+	flag.Int64Var(&conf.maxtSec, "maxt", conf.maxtSec, "Maximum timestamp (Unix seconds) for the migration.")
+	flag.StringVar(&conf.readURL, "read-url", conf.readURL, "URL of the remote-read storage endpoint to migrate data from.")
+	flag.StringVar(&conf.writeURL, "write-url", conf.writeURL, "URL of the remote-write storage endpoint to migrate data to.")
+	flag.StringVar(&conf.progressMetricName, "progress-metric-name", conf.progressMetricName, "Prometheus metric name used for tracking migration progress.")
+	flag.StringVar(&conf.progressMetricURL, "progress-metric-url", conf.progressMetricURL, "URL to fetch the progress metric from. "+
+		"If not set, progress-enabled migrations fall back to read-url.")
+	flag.StringVar(&conf.progressFile, "progress-file", conf.progressFile, "Path to a local JSON file used to checkpoint migration progress instead of progress-metric-url, "+
+		"so an interrupted migrator can resume from the last successfully migrated block.")
+	flag.BoolVar(&conf.progressEnabled, "progress-enabled", conf.progressEnabled, "Enable fetching and storing progress metrics, so a failed migration can resume.")
+	flag.StringVar(&conf.maxBlockSize, "max-read-size", conf.maxBlockSize, "Maximum size of data to be written to the write-storage in a single request batch, e.g. 500MB. "+
+		"Each block read from read-storage is split into smaller write requests if it would exceed this size.")
+	flag.IntVar(&conf.numShards, "num-shards", conf.numShards, "Number of concurrent shards to use while migrating data.")
+	flag.StringVar(&conf.relabelConfigFile, "relabel-config", conf.relabelConfigFile, "Path to a Prometheus-style relabel_configs YAML file, applied to every series between the remote-read "+
+		"response and the remote-write request. A series that relabels to nil is dropped from the migration.")
+
+	flag.StringVar(&conf.readerAuth.Username, "read-auth-username", conf.readerAuth.Username, "Username for basic-auth with the read storage.")
+	flag.StringVar(&conf.readerAuth.Password, "read-auth-password", conf.readerAuth.Password, "Password for basic-auth with the read storage.")
+	flag.StringVar(&conf.readerAuth.BearerToken, "read-auth-bearer-token", conf.readerAuth.BearerToken, "Bearer token for authentication with the read storage.")
+	flag.StringVar(&conf.readerAuth.BearerTokenFile, "read-auth-bearer-token-file", conf.readerAuth.BearerTokenFile, "File containing the bearer token for authentication with the read storage.")
+	flag.StringVar(&conf.readerAuth.TLSConfig.CAFile, "read-tls-ca-file", conf.readerAuth.TLSConfig.CAFile, "CA certificate file to validate the read storage's server certificate against.")
+	flag.StringVar(&conf.readerAuth.TLSConfig.CertFile, "read-tls-cert-file", conf.readerAuth.TLSConfig.CertFile, "Client certificate file for mTLS with the read storage.")
+	flag.StringVar(&conf.readerAuth.TLSConfig.KeyFile, "read-tls-key-file", conf.readerAuth.TLSConfig.KeyFile, "Client key file for mTLS with the read storage.")
+	flag.StringVar(&conf.readerAuth.TLSConfig.ServerName, "read-tls-server-name", conf.readerAuth.TLSConfig.ServerName, "Server name to verify the read storage's certificate against, overriding the hostname from read-url.")
+	flag.BoolVar(&conf.readerAuth.TLSConfig.InsecureSkipVerify, "read-tls-insecure-skip-verify", conf.readerAuth.TLSConfig.InsecureSkipVerify, "Disable verification of the read storage's server certificate.")
+
+	flag.StringVar(&conf.writerAuth.Username, "write-auth-username", conf.writerAuth.Username, "Username for basic-auth with the write storage.")
+	flag.StringVar(&conf.writerAuth.Password, "write-auth-password", conf.writerAuth.Password, "Password for basic-auth with the write storage.")
+	flag.StringVar(&conf.writerAuth.BearerToken, "write-auth-bearer-token", conf.writerAuth.BearerToken, "Bearer token for authentication with the write storage.")
+	flag.StringVar(&conf.writerAuth.BearerTokenFile, "write-auth-bearer-token-file", conf.writerAuth.BearerTokenFile, "File containing the bearer token for authentication with the write storage.")
+	flag.StringVar(&conf.writerAuth.TLSConfig.CAFile, "write-tls-ca-file", conf.writerAuth.TLSConfig.CAFile, "CA certificate file to validate the write storage's server certificate against.")
+	flag.StringVar(&conf.writerAuth.TLSConfig.CertFile, "write-tls-cert-file", conf.writerAuth.TLSConfig.CertFile, "Client certificate file for mTLS with the write storage.")
+	flag.StringVar(&conf.writerAuth.TLSConfig.KeyFile, "write-tls-key-file", conf.writerAuth.TLSConfig.KeyFile, "Client key file for mTLS with the write storage.")
+	flag.StringVar(&conf.writerAuth.TLSConfig.ServerName, "write-tls-server-name", conf.writerAuth.TLSConfig.ServerName, "Server name to verify the write storage's certificate against, overriding the hostname from write-url.")
+	flag.BoolVar(&conf.writerAuth.TLSConfig.InsecureSkipVerify, "write-tls-insecure-skip-verify", conf.writerAuth.TLSConfig.InsecureSkipVerify, "Disable verification of the write storage's server certificate.")
+
+	_ = flag.CommandLine.Parse(args)
+	flag.CommandLine.Visit(func(f *flag.Flag) {
+		if f.Name == "mint" {
+			conf.mintSet = true
+		}
+	})
+	convertSecFlagToMs(conf)
+}
+
+// extractConfigFlag scans args for a -config/--config value without
+// registering it on the main flag set, since the config file needs to be
+// loaded before the rest of the flags are registered with their defaults.
+func extractConfigFlag(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "-config" || arg == "--config":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "-config=") || strings.HasPrefix(arg, "--config="):
+			return arg[strings.Index(arg, "=")+1:]
+		}
+	}
+	return ""
+}
+
+// convertSecFlagToMs derives the millisecond timestamps used internally from
+// the second-granularity values accepted on the CLI and in config files.
+func convertSecFlagToMs(conf *config) {
+	conf.mint = conf.mintSec * 1000
+	conf.maxt = conf.maxtSec * 1000
+}
+
+func validateConf(conf *config) error {
+	if conf.configFileErr != nil {
+		return conf.configFileErr
+	}
+
+	if conf.mintSec == 0 && !conf.mintSet {
+		return fmt.Errorf("mint should be provided for the migration to begin")
+	}
+
+	readURL := strings.TrimSpace(conf.readURL)
+	writeURL := strings.TrimSpace(conf.writeURL)
+	switch {
+	case readURL == "" && writeURL == "":
+		return fmt.Errorf("remote read storage url and remote write storage url must be specified. Without these, data migration cannot begin")
+	case readURL == "":
+		return fmt.Errorf("remote read storage url needs to be specified. Without read storage url, data migration cannot begin")
+	case writeURL == "":
+		return fmt.Errorf("remote write storage url needs to be specified. Without write storage url, data migration cannot begin")
+	}
+
+	if conf.mint > conf.maxt {
+		return fmt.Errorf("invalid input: minimum timestamp value (mint) cannot be greater than the maximum timestamp value (maxt)")
+	}
+
+	if conf.progressEnabled && conf.progressMetricURL != "" && conf.progressFile != "" {
+		return fmt.Errorf("at most one of progress-metric-url and progress-file can be set when progress-enabled is true")
+	}
+
+	if _, err := parseByteSize(conf.maxBlockSize); err != nil {
+		return fmt.Errorf("parsing byte-size: %w", err)
+	}
+
+	if err := validateMetricName(conf.progressMetricName); err != nil {
+		return err
+	}
+
+	if conf.relabelConfigFile != "" {
+		cfgs, err := planner.LoadRelabelConfig(conf.relabelConfigFile)
+		if err != nil {
+			return fmt.Errorf("loading relabel config: %w", err)
+		}
+		conf.relabelConfigs = cfgs
+	}
+
+	if err := conf.readerAuth.Validate(); err != nil {
+		return fmt.Errorf("reader auth validation: %w", err)
+	}
+	if err := conf.writerAuth.Validate(); err != nil {
+		return fmt.Errorf("writer auth validation: %w", err)
+	}
+
+	return nil
+}
+
+func validateMetricName(name string) error {
+	if !metricNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid metric-name regex match: prom metric must match %s: recieved: %s", metricNameRegex.String(), name)
+	}
+	return nil
+}
+
+var byteSizeSuffixes = map[string]int64{
+	"B":  1,
+	"KB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+	"TB": 1 << 40,
+}
+
+// parseByteSize parses strings like "500MB" or "100 MB" into a number of
+// bytes.
+func parseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && (s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	numPart := s[:i]
+	suffix := strings.ToUpper(strings.TrimSpace(s[i:]))
+	if numPart == "" {
+		return 0, fmt.Errorf("Unrecognized size suffix %s", suffix)
+	}
+	multiplier, ok := byteSizeSuffixes[suffix]
+	if !ok {
+		return 0, fmt.Errorf("Unrecognized size suffix %s", suffix)
+	}
+	num, err := strconv.ParseFloat(numPart, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing numeric value %q: %w", numPart, err)
+	}
+	return int64(num * float64(multiplier)), nil
+}
@@ -30,6 +30,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -49,6 +50,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -68,6 +70,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -87,6 +90,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -107,6 +111,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -127,6 +132,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -146,6 +152,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -166,6 +173,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -204,6 +212,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000,
 				mintSec:            1,
+				mintSet:            true,
 				maxt:               time.Now().Unix() * 1000,
 				maxtSec:            time.Now().Unix(),
 				readURL:            "",
@@ -224,6 +233,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000,
 				mintSec:            1,
+				mintSet:            true,
 				maxt:               time.Now().Unix() * 1000,
 				maxtSec:            time.Now().Unix(),
 				readURL:            "  ",
@@ -244,6 +254,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000,
 				mintSec:            1,
+				mintSet:            true,
 				maxt:               time.Now().Unix() * 1000,
 				maxtSec:            time.Now().Unix(),
 				readURL:            "",
@@ -264,6 +275,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000,
 				mintSec:            1,
+				mintSet:            true,
 				maxt:               time.Now().Unix() * 1000,
 				maxtSec:            time.Now().Unix(),
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -284,6 +296,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000001000,
 				mintSec:            1000000001,
+				mintSet:            true,
 				maxt:               1000000000000,
 				maxtSec:            1000000000,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -304,6 +317,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000000001000,
 				mintSec:            1000000000001,
+				mintSet:            true,
 				maxt:               1000000000000000,
 				maxtSec:            1000000000000,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -324,6 +338,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               100000000000000,
 				mintSec:            100000000000,
+				mintSet:            true,
 				maxt:               1000000000000000,
 				maxtSec:            1000000000000,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -345,6 +360,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -365,6 +381,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -385,6 +402,7 @@ func TestParseFlags(t *testing.T) {
 				name:               "prom-migrator",
 				mint:               1000000,
 				mintSec:            1000,
+				mintSet:            true,
 				maxt:               1001000,
 				maxtSec:            1001,
 				readURL:            "http://localhost:9090/api/v1/read",
@@ -399,6 +417,136 @@ func TestParseFlags(t *testing.T) {
 			failsValidation: true,
 			errMessage:      `reader auth validation: at most one of basic_auth, bearer_token & bearer_token_file must be configured`,
 		},
+		{
+			name:  "fail_non_exclusive_bearer_token_file_and_password",
+			input: []string{"-mint=1000", "-maxt=1001", "-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write", "-progress-enabled=false", "-read-auth-password=password", "-read-auth-bearer-token-file=/tmp/token"},
+			expectedConf: &config{
+				name:               "prom-migrator",
+				mint:               1000000,
+				mintSec:            1000,
+				mintSet:            true,
+				maxt:               1001000,
+				maxtSec:            1001,
+				readURL:            "http://localhost:9090/api/v1/read",
+				writeURL:           "http://localhost:9201/write",
+				progressMetricName: "prom_migrator_progress",
+				progressMetricURL:  "",
+				maxBlockSize:       "500MB",
+				numShards:          4,
+				progressEnabled:    false,
+				readerAuth:         utils.Auth{Password: "password", BearerTokenFile: "/tmp/token"},
+			},
+			failsValidation: true,
+			errMessage:      `reader auth validation: at most one of basic_auth, bearer_token & bearer_token_file must be configured`,
+		},
+		{
+			name:  "fail_tls_cert_without_key",
+			input: []string{"-mint=1000", "-maxt=1001", "-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write", "-progress-enabled=false", "-read-tls-cert-file=/tmp/cert.pem"},
+			expectedConf: &config{
+				name:               "prom-migrator",
+				mint:               1000000,
+				mintSec:            1000,
+				mintSet:            true,
+				maxt:               1001000,
+				maxtSec:            1001,
+				readURL:            "http://localhost:9090/api/v1/read",
+				writeURL:           "http://localhost:9201/write",
+				progressMetricName: "prom_migrator_progress",
+				progressMetricURL:  "",
+				maxBlockSize:       "500MB",
+				numShards:          4,
+				progressEnabled:    false,
+				readerAuth:         utils.Auth{TLSConfig: utils.TLSConfig{CertFile: "/tmp/cert.pem"}},
+			},
+			failsValidation: true,
+			errMessage:      `reader auth validation: tls cert file and key file must both be provided, or neither`,
+		},
+		{
+			name:  "pass_progress_file",
+			input: []string{"-mint=1000", "-maxt=1001", "-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write", "-progress-file=/tmp/prom-migrator-progress.json"},
+			expectedConf: &config{
+				name:               "prom-migrator",
+				mint:               1000000,
+				mintSec:            1000,
+				mintSet:            true,
+				maxt:               1001000,
+				maxtSec:            1001,
+				readURL:            "http://localhost:9090/api/v1/read",
+				writeURL:           "http://localhost:9201/write",
+				progressMetricName: "prom_migrator_progress",
+				progressMetricURL:  "",
+				progressFile:       "/tmp/prom-migrator-progress.json",
+				progressEnabled:    true,
+				maxBlockSize:       "500MB",
+				numShards:          4,
+			},
+			failsValidation: false,
+		},
+		{
+			name:  "fail_progress_metric_url_and_progress_file_both_set",
+			input: []string{"-mint=1000", "-maxt=1001", "-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write", "-progress-metric-url=http://localhost:9201/read", "-progress-file=/tmp/prom-migrator-progress.json"},
+			expectedConf: &config{
+				name:               "prom-migrator",
+				mint:               1000000,
+				mintSec:            1000,
+				mintSet:            true,
+				maxt:               1001000,
+				maxtSec:            1001,
+				readURL:            "http://localhost:9090/api/v1/read",
+				writeURL:           "http://localhost:9201/write",
+				progressMetricName: "prom_migrator_progress",
+				progressMetricURL:  "http://localhost:9201/read",
+				progressFile:       "/tmp/prom-migrator-progress.json",
+				progressEnabled:    true,
+				maxBlockSize:       "500MB",
+				numShards:          4,
+			},
+			failsValidation: true,
+			errMessage:      `at most one of progress-metric-url and progress-file can be set when progress-enabled is true`,
+		},
+		{
+			name:  "pass_progress_metric_url_and_progress_file_both_set_but_progress_disabled",
+			input: []string{"-mint=1000", "-maxt=1001", "-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write", "-progress-enabled=false", "-progress-metric-url=http://localhost:9201/read", "-progress-file=/tmp/prom-migrator-progress.json"},
+			expectedConf: &config{
+				name:               "prom-migrator",
+				mint:               1000000,
+				mintSec:            1000,
+				mintSet:            true,
+				maxt:               1001000,
+				maxtSec:            1001,
+				readURL:            "http://localhost:9090/api/v1/read",
+				writeURL:           "http://localhost:9201/write",
+				progressMetricName: "prom_migrator_progress",
+				progressMetricURL:  "http://localhost:9201/read",
+				progressFile:       "/tmp/prom-migrator-progress.json",
+				progressEnabled:    false,
+				maxBlockSize:       "500MB",
+				numShards:          4,
+			},
+			failsValidation: false,
+		},
+		{
+			name:  "fail_relabel_config_file_not_found",
+			input: []string{"-mint=1000", "-maxt=1001", "-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write", "-progress-enabled=false", "-relabel-config=/does/not/exist.yaml"},
+			expectedConf: &config{
+				name:               "prom-migrator",
+				mint:               1000000,
+				mintSec:            1000,
+				mintSet:            true,
+				maxt:               1001000,
+				maxtSec:            1001,
+				readURL:            "http://localhost:9090/api/v1/read",
+				writeURL:           "http://localhost:9201/write",
+				progressMetricName: "prom_migrator_progress",
+				progressMetricURL:  "",
+				progressEnabled:    false,
+				maxBlockSize:       "500MB",
+				numShards:          4,
+				relabelConfigFile:  "/does/not/exist.yaml",
+			},
+			failsValidation: true,
+			errMessage:      `loading relabel config: reading relabel config file "/does/not/exist.yaml": open /does/not/exist.yaml: no such file or directory`,
+		},
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,151 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/timescale/promscale/pkg/migration-tool/planner"
+	"github.com/timescale/promscale/pkg/migration-tool/reader"
+	"github.com/timescale/promscale/pkg/migration-tool/writer"
+)
+
+// fakeReadServer answers every remote-read query with a single series named
+// after the query's start timestamp, so a test can tell which block a given
+// read request was for.
+func fakeReadServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		var req prompb.ReadRequest
+		require.NoError(t, proto.Unmarshal(decoded, &req))
+
+		mint := req.Queries[0].StartTimestampMs
+		series := prompb.TimeSeries{
+			Labels:  []prompb.Label{{Name: "__name__", Value: fmt.Sprintf("series_%d", mint)}},
+			Samples: []prompb.Sample{{Timestamp: mint, Value: float64(mint)}},
+		}
+		resp := &prompb.ReadResponse{Results: []*prompb.QueryResult{{Timeseries: []prompb.TimeSeries{series}}}}
+		data, err := proto.Marshal(resp)
+		require.NoError(t, err)
+		_, err = w.Write(snappy.Encode(nil, data))
+		require.NoError(t, err)
+	}))
+}
+
+// recordingWriteServer decodes every remote-write request it receives and
+// appends the series it contained, so a test can assert on how many
+// requests were sent and what ended up in them.
+type recordingWriteServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests [][]prompb.TimeSeries
+}
+
+func newRecordingWriteServer(t *testing.T) *recordingWriteServer {
+	t.Helper()
+	rec := &recordingWriteServer{}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, proto.Unmarshal(decoded, &req))
+
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, req.Timeseries)
+		rec.mu.Unlock()
+	}))
+	return rec
+}
+
+func (rec *recordingWriteServer) allSeries() []prompb.TimeSeries {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	var all []prompb.TimeSeries
+	for _, req := range rec.requests {
+		all = append(all, req...)
+	}
+	return all
+}
+
+func TestMigrateShardsAcrossBlocksAndCheckpoints(t *testing.T) {
+	readSrv := fakeReadServer(t)
+	defer readSrv.Close()
+	writeSrv := newRecordingWriteServer(t)
+	defer writeSrv.Close()
+
+	rdr := reader.New(readSrv.Client(), readSrv.URL)
+	wtr := writer.New(writeSrv.Client(), writeSrv.URL)
+
+	progressFile := filepath.Join(t.TempDir(), "progress.json")
+	conf := &config{mint: 0, maxt: 1000, numShards: 4, progressFile: progressFile, progressEnabled: true}
+
+	require.NoError(t, migrate(rdr, wtr, conf, 0))
+
+	assert.Len(t, writeSrv.allSeries(), 4, "one series per shard block")
+
+	cp, err := planner.ReadCheckpoint(progressFile)
+	require.NoError(t, err)
+	require.NotNil(t, cp)
+	assert.Equal(t, int64(1000), cp.Maxt, "checkpoint should advance to the final block's maxt once every block completes")
+}
+
+func TestMigrateSplitsOversizedWritesWithoutRereading(t *testing.T) {
+	readCalls := 0
+	readSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readCalls++
+		series := make([]prompb.TimeSeries, 4)
+		for i := range series {
+			series[i] = prompb.TimeSeries{
+				Labels:  []prompb.Label{{Name: "__name__", Value: fmt.Sprintf("series_%d", i)}},
+				Samples: []prompb.Sample{{Timestamp: int64(i), Value: float64(i)}},
+			}
+		}
+		resp := &prompb.ReadResponse{Results: []*prompb.QueryResult{{Timeseries: series}}}
+		data, err := proto.Marshal(resp)
+		require.NoError(t, err)
+		_, err = w.Write(snappy.Encode(nil, data))
+		require.NoError(t, err)
+	}))
+	defer readSrv.Close()
+	writeSrv := newRecordingWriteServer(t)
+	defer writeSrv.Close()
+
+	rdr := reader.New(readSrv.Client(), readSrv.URL)
+	wtr := writer.New(writeSrv.Client(), writeSrv.URL)
+	conf := &config{mint: 0, maxt: 1000, numShards: 1}
+
+	require.NoError(t, migrate(rdr, wtr, conf, 1 /* bytes: force every multi-series write to split */))
+
+	assert.Equal(t, 1, readCalls, "a single block should only be read once, even if its write gets split")
+	assert.Len(t, writeSrv.requests, 4, "each series should end up in its own write request")
+	assert.Len(t, writeSrv.allSeries(), 4)
+}
+
+func TestWriteInChunksEmptySeries(t *testing.T) {
+	writeSrv := newRecordingWriteServer(t)
+	defer writeSrv.Close()
+	wtr := writer.New(writeSrv.Client(), writeSrv.URL)
+
+	require.NoError(t, writeInChunks(wtr, nil, 500))
+	assert.Empty(t, writeSrv.requests, "writer.Write is a no-op for an empty series slice")
+}
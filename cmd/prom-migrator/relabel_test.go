@@ -0,0 +1,60 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFlagsRelabelConfig covers the -relabel-config positive-parse and
+// invalid-regex cases, which need a real file on disk and so don't fit the
+// static string-literal cases in TestParseFlags.
+func TestParseFlagsRelabelConfig(t *testing.T) {
+	path := writeTempFile(t, t.TempDir(), "relabel.yaml", `
+- source_labels: [__name__]
+  regex: "dropped_metric"
+  action: drop
+- target_label: cluster
+  replacement: prod
+`)
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	conf := new(config)
+	parseFlags(conf, []string{
+		"-mint=1000", "-maxt=1001",
+		"-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write",
+		"-progress-enabled=false",
+		"-relabel-config=" + path,
+	})
+
+	require.NoError(t, validateConf(conf))
+	require.Len(t, conf.relabelConfigs, 2)
+}
+
+func TestParseFlagsRelabelConfigInvalidRegex(t *testing.T) {
+	path := writeTempFile(t, t.TempDir(), "relabel.yaml", `
+- source_labels: [__name__]
+  regex: "("
+  action: drop
+`)
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	conf := new(config)
+	parseFlags(conf, []string{
+		"-mint=1000", "-maxt=1001",
+		"-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write",
+		"-progress-enabled=false",
+		"-relabel-config=" + path,
+	})
+
+	err := validateConf(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading relabel config")
+}
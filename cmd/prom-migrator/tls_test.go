@@ -0,0 +1,68 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFlagsTLS covers the mTLS/CA-bundle flags, which need readable
+// files on disk to pass validateConf and so don't fit the static
+// string-literal cases in TestParseFlags.
+func TestParseFlagsTLS(t *testing.T) {
+	dir := t.TempDir()
+	caFile := writeTempFile(t, dir, "ca.pem", "ca")
+	certFile := writeTempFile(t, dir, "cert.pem", "cert")
+	keyFile := writeTempFile(t, dir, "key.pem", "key")
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	conf := new(config)
+	parseFlags(conf, []string{
+		"-mint=1000", "-maxt=1001",
+		"-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write",
+		"-progress-enabled=false",
+		"-read-tls-ca-file=" + caFile,
+		"-read-tls-cert-file=" + certFile,
+		"-read-tls-key-file=" + keyFile,
+		"-read-tls-server-name=prometheus.internal",
+		"-read-tls-insecure-skip-verify=true",
+	})
+
+	assert.Equal(t, caFile, conf.readerAuth.TLSConfig.CAFile)
+	assert.Equal(t, certFile, conf.readerAuth.TLSConfig.CertFile)
+	assert.Equal(t, keyFile, conf.readerAuth.TLSConfig.KeyFile)
+	assert.Equal(t, "prometheus.internal", conf.readerAuth.TLSConfig.ServerName)
+	assert.True(t, conf.readerAuth.TLSConfig.InsecureSkipVerify)
+
+	require.NoError(t, validateConf(conf))
+}
+
+func TestParseFlagsTLSUnreadableFile(t *testing.T) {
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	conf := new(config)
+	parseFlags(conf, []string{
+		"-mint=1000", "-maxt=1001",
+		"-read-url=http://localhost:9090/api/v1/read", "-write-url=http://localhost:9201/write",
+		"-progress-enabled=false",
+		"-read-tls-ca-file=/does/not/exist.pem",
+	})
+
+	err := validateConf(conf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not readable")
+}
+
+func writeTempFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
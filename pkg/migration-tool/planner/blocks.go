@@ -0,0 +1,57 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package planner
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Block is a contiguous, half-open millisecond time-range to migrate in a
+// single remote-read/remote-write round trip.
+type Block struct {
+	Mint int64
+	Maxt int64
+}
+
+// SplitBlocks splits [mint, maxt] into numShards contiguous, equal-width
+// blocks in chronological order, so they can be migrated concurrently
+// without overlapping. numShards <= 1 returns a single block covering the
+// whole range, and so does a zero-width range (mint == maxt), rather than
+// zero blocks - an empty [mint, maxt] is still a block worth reading once.
+func SplitBlocks(mint, maxt int64, numShards int) []Block {
+	if numShards < 1 {
+		numShards = 1
+	}
+	if maxt <= mint {
+		return []Block{{Mint: mint, Maxt: maxt}}
+	}
+
+	width := (maxt - mint) / int64(numShards)
+	if width < 1 {
+		width = 1
+	}
+
+	blocks := make([]Block, 0, numShards)
+	for start := mint; start < maxt; start += width {
+		end := start + width
+		if end > maxt || len(blocks) == numShards-1 {
+			end = maxt
+		}
+		blocks = append(blocks, Block{Mint: start, Maxt: end})
+		if end == maxt {
+			break
+		}
+	}
+	return blocks
+}
+
+// EstimateSize returns the approximate wire size, in bytes, of series once
+// marshalled into a remote-write request. It's used to decide whether a
+// block read from the source needs to be split further before being
+// written downstream.
+func EstimateSize(series []prompb.TimeSeries) int64 {
+	return int64(proto.Size(&prompb.WriteRequest{Timeseries: series}))
+}
@@ -0,0 +1,80 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package planner
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitBlocks(t *testing.T) {
+	cases := []struct {
+		name      string
+		mint      int64
+		maxt      int64
+		numShards int
+		expected  []Block
+	}{
+		{
+			name:      "four_even_shards",
+			mint:      0,
+			maxt:      1000,
+			numShards: 4,
+			expected: []Block{
+				{Mint: 0, Maxt: 250},
+				{Mint: 250, Maxt: 500},
+				{Mint: 500, Maxt: 750},
+				{Mint: 750, Maxt: 1000},
+			},
+		},
+		{
+			name:      "single_shard",
+			mint:      0,
+			maxt:      1000,
+			numShards: 1,
+			expected:  []Block{{Mint: 0, Maxt: 1000}},
+		},
+		{
+			name:      "zero_shards_defaults_to_one",
+			mint:      0,
+			maxt:      1000,
+			numShards: 0,
+			expected:  []Block{{Mint: 0, Maxt: 1000}},
+		},
+		{
+			name:      "range_narrower_than_shard_count",
+			mint:      0,
+			maxt:      2,
+			numShards: 4,
+			expected:  []Block{{Mint: 0, Maxt: 1}, {Mint: 1, Maxt: 2}},
+		},
+		{
+			name:      "zero_width_range_still_yields_one_block",
+			mint:      1000,
+			maxt:      1000,
+			numShards: 4,
+			expected:  []Block{{Mint: 1000, Maxt: 1000}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			blocks := SplitBlocks(c.mint, c.maxt, c.numShards)
+			assert.Equal(t, c.expected, blocks)
+		})
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	empty := EstimateSize(nil)
+	assert.Zero(t, empty)
+
+	withSeries := EstimateSize([]prompb.TimeSeries{
+		{Labels: []prompb.Label{{Name: "__name__", Value: "up"}}},
+	})
+	assert.Greater(t, withSeries, empty)
+}
@@ -0,0 +1,143 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+// Package planner splits a migration into blocks that can be migrated
+// concurrently, applies relabeling to the series in each block, and tracks
+// where to resume from after an interrupted run - either from a local
+// progress-file checkpoint or from a remote progress metric.
+package planner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Checkpoint is the on-disk representation of a -progress-file: the maxt of
+// the last block that was successfully migrated.
+type Checkpoint struct {
+	Maxt int64 `json:"maxt"`
+}
+
+// ReadCheckpoint reads the checkpoint at path. It returns a nil Checkpoint
+// and no error if the file does not exist yet, since that's the normal case
+// for a migration that hasn't run before.
+func ReadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading progress file %q: %w", path, err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parsing progress file %q: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// WriteCheckpoint persists maxt to path, replacing any previous checkpoint.
+// It writes to a temp file in the same directory, fsyncs it, and renames it
+// over path, so a migrator killed mid-write leaves the previous checkpoint
+// intact rather than a half-written one.
+func WriteCheckpoint(path string, maxt int64) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".progress-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp progress file in %q: %w", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(Checkpoint{Maxt: maxt}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing progress file %q: %w", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("fsyncing progress file %q: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing progress file %q: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("renaming progress file %q to %q: %w", tmp.Name(), path, err)
+	}
+	return nil
+}
+
+// SeedMint returns the mint a migration should resume from: the last
+// checkpointed maxt from progressFile if one is set and a checkpoint
+// exists, otherwise defaultMint.
+func SeedMint(progressFile string, defaultMint int64) (int64, error) {
+	if progressFile == "" {
+		return defaultMint, nil
+	}
+	cp, err := ReadCheckpoint(progressFile)
+	if err != nil {
+		return 0, err
+	}
+	if cp == nil {
+		return defaultMint, nil
+	}
+	return cp.Maxt, nil
+}
+
+// metricQueryResponse is the subset of a Prometheus HTTP API instant-query
+// response (`/api/v1/query`) that SeedMintFromMetric needs.
+type metricQueryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		Result []struct {
+			// Value is [unix-seconds-of-sample, string-encoded-value].
+			Value [2]interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// SeedMintFromMetric returns the mint a migration should resume from by
+// running an instant PromQL query for metricName against metricURL's
+// Prometheus HTTP API and reading its value back as a millisecond
+// timestamp. It returns defaultMint if the metric has no samples yet, which
+// is the normal case for a migration that hasn't run before.
+func SeedMintFromMetric(client *http.Client, metricURL, metricName string, defaultMint int64) (int64, error) {
+	queryURL := strings.TrimRight(metricURL, "/") + "/api/v1/query?query=" + url.QueryEscape(metricName)
+	resp, err := client.Get(queryURL)
+	if err != nil {
+		return 0, fmt.Errorf("querying progress metric %q: %w", metricName, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("reading progress metric response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("querying progress metric %q returned status %d: %s", metricName, resp.StatusCode, body)
+	}
+
+	var qr metricQueryResponse
+	if err := json.Unmarshal(body, &qr); err != nil {
+		return 0, fmt.Errorf("parsing progress metric response: %w", err)
+	}
+	if qr.Status != "success" || len(qr.Data.Result) == 0 {
+		return defaultMint, nil
+	}
+
+	valueStr, ok := qr.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected value type in progress metric %q response", metricName)
+	}
+	seconds, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing progress metric %q value %q: %w", metricName, valueStr, err)
+	}
+	return int64(seconds * 1000), nil
+}
@@ -0,0 +1,100 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package planner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReadCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.json")
+
+	require.NoError(t, WriteCheckpoint(path, 1000))
+	cp, err := ReadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), cp.Maxt)
+
+	// A later checkpoint replaces the earlier one.
+	require.NoError(t, WriteCheckpoint(path, 2000))
+	cp, err = ReadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), cp.Maxt)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no leftover temp files should remain after a successful write")
+}
+
+func TestReadCheckpointMissing(t *testing.T) {
+	cp, err := ReadCheckpoint(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+}
+
+func TestSeedMintNoProgressFile(t *testing.T) {
+	mint, err := SeedMint("", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), mint)
+}
+
+func TestSeedMintNoExistingCheckpoint(t *testing.T) {
+	progressFile := filepath.Join(t.TempDir(), "progress.json")
+	mint, err := SeedMint(progressFile, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), mint)
+}
+
+func TestSeedMintExistingCheckpoint(t *testing.T) {
+	progressFile := filepath.Join(t.TempDir(), "progress.json")
+	require.NoError(t, WriteCheckpoint(progressFile, 5000))
+
+	mint, err := SeedMint(progressFile, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000), mint)
+}
+
+func TestSeedMintFromMetricNoSamples(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer srv.Close()
+
+	mint, err := SeedMintFromMetric(srv.Client(), srv.URL, "prom_migrator_progress", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), mint)
+}
+
+func TestSeedMintFromMetricExistingSample(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.String(), "/api/v1/query?query=prom_migrator_progress")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"5"]}]}}`))
+	}))
+	defer srv.Close()
+
+	mint, err := SeedMintFromMetric(srv.Client(), srv.URL, "prom_migrator_progress", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000), mint)
+}
+
+func TestSeedMintFromMetricErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("boom"))
+	}))
+	defer srv.Close()
+
+	_, err := SeedMintFromMetric(srv.Client(), srv.URL, "prom_migrator_progress", 1000)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
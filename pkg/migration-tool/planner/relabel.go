@@ -0,0 +1,67 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package planner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/relabel"
+	"github.com/prometheus/prometheus/prompb"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadRelabelConfig reads a Prometheus-style relabel_configs YAML file (a
+// top-level list of relabel rules) from path. Unmarshalling also compiles
+// every rule's regex, so a malformed pattern is caught here rather than on
+// the first series it's applied to.
+func LoadRelabelConfig(path string) ([]*relabel.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading relabel config file %q: %w", path, err)
+	}
+	var cfgs []*relabel.Config
+	if err := yaml.UnmarshalStrict(data, &cfgs); err != nil {
+		return nil, fmt.Errorf("parsing relabel config file %q: %w", path, err)
+	}
+	return cfgs, nil
+}
+
+// ApplyRelabeling runs relabel.Process on every series in the block,
+// dropping a series entirely if it relabels to nil. cfgs may be empty, in
+// which case series are returned unmodified.
+func ApplyRelabeling(series []prompb.TimeSeries, cfgs []*relabel.Config) []prompb.TimeSeries {
+	if len(cfgs) == 0 {
+		return series
+	}
+
+	kept := series[:0]
+	for _, s := range series {
+		relabelled := relabel.Process(seriesLabels(s), cfgs...)
+		if relabelled == nil {
+			continue
+		}
+		s.Labels = labelsToPrompb(relabelled)
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+func seriesLabels(s prompb.TimeSeries) labels.Labels {
+	lbls := make([]labels.Label, len(s.Labels))
+	for i, l := range s.Labels {
+		lbls[i] = labels.Label{Name: l.Name, Value: l.Value}
+	}
+	return labels.New(lbls...)
+}
+
+func labelsToPrompb(lbls labels.Labels) []prompb.Label {
+	out := make([]prompb.Label, len(lbls))
+	for i, l := range lbls {
+		out[i] = prompb.Label{Name: l.Name, Value: l.Value}
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package planner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRelabelConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relabel.yaml")
+	contents := `
+- source_labels: [__name__]
+  regex: "dropped_metric"
+  action: drop
+- target_label: cluster
+  replacement: prod
+`
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	cfgs, err := LoadRelabelConfig(path)
+	require.NoError(t, err)
+	assert.Len(t, cfgs, 2)
+}
+
+func TestLoadRelabelConfigNotFound(t *testing.T) {
+	_, err := LoadRelabelConfig(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading relabel config file")
+}
+
+func TestLoadRelabelConfigInvalidRegex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relabel.yaml")
+	contents := `
+- source_labels: [__name__]
+  regex: "("
+  action: drop
+`
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+	_, err := LoadRelabelConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing relabel config file")
+}
+
+func TestApplyRelabelingDropsAndRenames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "relabel.yaml")
+	contents := `
+- source_labels: [__name__]
+  regex: "dropped_metric"
+  action: drop
+- target_label: cluster
+  replacement: prod
+`
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	cfgs, err := LoadRelabelConfig(path)
+	require.NoError(t, err)
+
+	series := []prompb.TimeSeries{
+		{Labels: []prompb.Label{{Name: "__name__", Value: "dropped_metric"}}},
+		{Labels: []prompb.Label{{Name: "__name__", Value: "kept_metric"}}},
+	}
+
+	out := ApplyRelabeling(series, cfgs)
+	require.Len(t, out, 1)
+
+	labelMap := map[string]string{}
+	for _, l := range out[0].Labels {
+		labelMap[l.Name] = l.Value
+	}
+	assert.Equal(t, "kept_metric", labelMap["__name__"])
+	assert.Equal(t, "prod", labelMap["cluster"])
+}
+
+func TestApplyRelabelingNoConfigs(t *testing.T) {
+	series := []prompb.TimeSeries{
+		{Labels: []prompb.Label{{Name: "__name__", Value: "a_metric"}}},
+	}
+	out := ApplyRelabeling(series, nil)
+	assert.Equal(t, series, out)
+}
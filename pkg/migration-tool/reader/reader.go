@@ -0,0 +1,81 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+// Package reader fetches time series from a Prometheus remote-read
+// endpoint for a given time-range.
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Reader reads time series in [mint, maxt] from a single remote-read URL.
+type Reader struct {
+	client *http.Client
+	url    string
+}
+
+// New returns a Reader that issues remote-read requests against url using
+// client. client should already be configured with any auth/TLS settings
+// for the endpoint.
+func New(client *http.Client, url string) *Reader {
+	return &Reader{client: client, url: url}
+}
+
+// Read fetches every series with at least one sample in [mint, maxt].
+func (r *Reader) Read(mint, maxt int64) ([]prompb.TimeSeries, error) {
+	req := &prompb.ReadRequest{
+		Queries: []*prompb.Query{
+			{StartTimestampMs: mint, EndTimestampMs: maxt},
+		},
+	}
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling remote-read request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return nil, fmt.Errorf("building remote-read request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("X-Prometheus-Remote-Read-Version", "0.1.0")
+
+	resp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("remote-read request to %q: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading remote-read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote-read request to %q returned status %d: %s", r.url, resp.StatusCode, body)
+	}
+
+	decoded, err := snappy.Decode(nil, body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding remote-read response: %w", err)
+	}
+	var readResp prompb.ReadResponse
+	if err := proto.Unmarshal(decoded, &readResp); err != nil {
+		return nil, fmt.Errorf("unmarshaling remote-read response: %w", err)
+	}
+
+	var series []prompb.TimeSeries
+	for _, result := range readResp.Results {
+		series = append(series, result.Timeseries...)
+	}
+	return series, nil
+}
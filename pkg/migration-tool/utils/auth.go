@@ -0,0 +1,76 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Auth holds the credentials used to authenticate against a remote-read or
+// remote-write endpoint. At most one authentication mechanism may be
+// configured at a time.
+type Auth struct {
+	Username        string `yaml:"username,omitempty" toml:"username"`
+	Password        string `yaml:"password,omitempty" toml:"password"`
+	BearerToken     string `yaml:"bearer_token,omitempty" toml:"bearer_token"`
+	BearerTokenFile string `yaml:"bearer_token_file,omitempty" toml:"bearer_token_file"`
+
+	TLSConfig TLSConfig `yaml:"tls_config,omitempty" toml:"tls_config"`
+}
+
+// Validate returns an error if more than one authentication mechanism is
+// configured, since only one can be applied to a request at a time, or if
+// the TLS settings are inconsistent.
+func (a Auth) Validate() error {
+	numSet := 0
+	if a.Username != "" || a.Password != "" {
+		numSet++
+	}
+	if a.BearerToken != "" {
+		numSet++
+	}
+	if a.BearerTokenFile != "" {
+		numSet++
+	}
+	if numSet > 1 {
+		return fmt.Errorf("at most one of basic_auth, bearer_token & bearer_token_file must be configured")
+	}
+	if err := a.TLSConfig.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// authRoundTripper sets the Authorization header implied by auth on every
+// request before delegating to next. BearerTokenFile is re-read on every
+// request, rather than once up front, so a token that's rotated on disk
+// (e.g. a Kubernetes projected service-account token) is picked up without
+// restarting the migrator.
+type authRoundTripper struct {
+	auth Auth
+	next http.RoundTripper
+}
+
+func (rt *authRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case rt.auth.BearerTokenFile != "":
+		token, err := os.ReadFile(rt.auth.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading bearer token file %q: %w", rt.auth.BearerTokenFile, err)
+		}
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	case rt.auth.BearerToken != "":
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+rt.auth.BearerToken)
+	case rt.auth.Username != "" || rt.auth.Password != "":
+		req = req.Clone(req.Context())
+		req.SetBasicAuth(rt.auth.Username, rt.auth.Password)
+	}
+	return rt.next.RoundTrip(req)
+}
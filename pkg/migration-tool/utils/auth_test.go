@@ -0,0 +1,79 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+package utils
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingRoundTripper captures the request it receives instead of sending
+// it anywhere, so tests can assert on the headers authRoundTripper set.
+type recordingRoundTripper struct {
+	req *http.Request
+}
+
+func (rt *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.req = req
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestAuthRoundTripper(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o644))
+
+	cases := []struct {
+		name         string
+		auth         Auth
+		expectHeader string
+		expectAbsent bool
+	}{
+		{
+			name:         "no_auth",
+			auth:         Auth{},
+			expectAbsent: true,
+		},
+		{
+			name:         "basic_auth",
+			auth:         Auth{Username: "user", Password: "pass"},
+			expectHeader: "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:         "bearer_token",
+			auth:         Auth{BearerToken: "abc123"},
+			expectHeader: "Bearer abc123",
+		},
+		{
+			name:         "bearer_token_file",
+			auth:         Auth{BearerTokenFile: tokenFile},
+			expectHeader: "Bearer file-token",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next := &recordingRoundTripper{}
+			rt := &authRoundTripper{auth: c.auth, next: next}
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			require.NoError(t, err)
+
+			_, err = rt.RoundTrip(req)
+			require.NoError(t, err)
+
+			if c.expectAbsent {
+				assert.Empty(t, next.req.Header.Get("Authorization"))
+				return
+			}
+			assert.Equal(t, c.expectHeader, next.req.Header.Get("Authorization"))
+		})
+	}
+}
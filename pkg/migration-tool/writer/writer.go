@@ -0,0 +1,61 @@
+// This file and its contents are licensed under the Apache License 2.0.
+// Please see the included NOTICE for copyright information and
+// LICENSE for a copy of the license.
+
+// Package writer pushes time series to a Prometheus remote-write endpoint.
+package writer
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+// Writer pushes time series to a single remote-write URL.
+type Writer struct {
+	client *http.Client
+	url    string
+}
+
+// New returns a Writer that issues remote-write requests against url using
+// client. client should already be configured with any auth/TLS settings
+// for the endpoint.
+func New(client *http.Client, url string) *Writer {
+	return &Writer{client: client, url: url}
+}
+
+// Write pushes series to the remote-write endpoint in a single request.
+func (w *Writer) Write(series []prompb.TimeSeries) error {
+	if len(series) == 0 {
+		return nil
+	}
+
+	req := &prompb.WriteRequest{Timeseries: series}
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshaling remote-write request: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(snappy.Encode(nil, data)))
+	if err != nil {
+		return fmt.Errorf("building remote-write request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("Content-Encoding", "snappy")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("remote-write request to %q: %w", w.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("remote-write request to %q returned status %d", w.url, resp.StatusCode)
+	}
+	return nil
+}